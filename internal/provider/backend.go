@@ -0,0 +1,24 @@
+package provider
+
+import "context"
+
+// DNSBackend is the set of operations Provider needs from a concrete DNS API
+// client. Implementations, e.g. internal/stackitprovider/backends/stackit,
+// translate these generic calls into that API's own request/response
+// shapes and error classification. Keeping reconciliation, batching, retry
+// and the worker pool here means all of that logic is shared by every
+// backend, including a mock used in tests, instead of being duplicated per
+// provider.
+type DNSBackend interface {
+	// Zones returns every zone this backend is configured to manage.
+	Zones(ctx context.Context) ([]Zone, error)
+	// ListRRSets returns every record set currently live in the given zone.
+	ListRRSets(ctx context.Context, zoneID string) ([]RRSet, error)
+	// CreateRRSet creates a new record set in the given zone.
+	CreateRRSet(ctx context.Context, zoneID string, rrSet RRSet) error
+	// UpdateRRSet overrides the contents of an existing record set,
+	// identified by rrSet.ID.
+	UpdateRRSet(ctx context.Context, zoneID string, rrSet RRSet) error
+	// DeleteRRSet deletes an existing record set, identified by rrSet.ID.
+	DeleteRRSet(ctx context.Context, zoneID string, rrSet RRSet) error
+}
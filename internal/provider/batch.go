@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+const (
+	// CREATE identifies a record set that should be created.
+	CREATE = "CREATE"
+	// UPDATE identifies a record set whose contents should be overridden.
+	UPDATE = "UPDATE"
+	// DELETE identifies a record set that should be removed.
+	DELETE = "DELETE"
+)
+
+// changeKey identifies a single RRSet within a zone, used to deduplicate
+// changes that target the same record within one plan.Changes.
+type changeKey struct {
+	zoneID string
+	name   string
+	rtype  string
+}
+
+// zoneBatch collects the deduplicated creates, updates and deletes that
+// resolved to a single zone.
+type zoneBatch struct {
+	zone    Zone
+	creates []*endpoint.Endpoint
+	updates []*endpoint.Endpoint
+	deletes []*endpoint.Endpoint
+}
+
+// zoneActionBatch is a chunk of same-action changes for a single zone, sized
+// to at most MaxChangesPerBatch entries, ready to be handed to a worker.
+type zoneActionBatch struct {
+	zone    Zone
+	action  string
+	changes []*endpoint.Endpoint
+}
+
+// buildZoneBatches resolves every endpoint in changes to its matching zone
+// via findBestMatchingZone and deduplicates entries that target the same
+// (zone, name, type) tuple: Create/Update changes overwrite earlier ones for
+// the same tuple (last-write-wins), while a Delete for that tuple always
+// wins regardless of order. Each endpoint is normalized (see normalizeChange)
+// before its changeKey is computed, so two endpoints for the same record that
+// differ only by external-dns's trailing FQDN dot still collide into one
+// decision instead of both being applied.
+func buildZoneBatches(changes *plan.Changes, zones []Zone) (map[string]*zoneBatch, error) {
+	type decision struct {
+		endpoint *endpoint.Endpoint
+		zone     Zone
+		action   string
+	}
+
+	decided := map[changeKey]decision{}
+
+	record := func(endpoints []*endpoint.Endpoint, action string) error {
+		for _, ep := range endpoints {
+			normalizeChange(ep)
+
+			resultZone, found := findBestMatchingZone(ep.DNSName, zones)
+			if !found {
+				return &ProviderError{
+					Action:     action,
+					DNSName:    ep.DNSName,
+					RecordType: ep.RecordType,
+					Err:        ErrZoneNotFound,
+				}
+			}
+
+			key := changeKey{zoneID: resultZone.ID, name: ep.DNSName, rtype: ep.RecordType}
+			if existing, ok := decided[key]; ok && existing.action == DELETE {
+				continue
+			}
+
+			decided[key] = decision{endpoint: ep, zone: resultZone, action: action}
+		}
+
+		return nil
+	}
+
+	if err := record(changes.Create, CREATE); err != nil {
+		return nil, err
+	}
+
+	if err := record(changes.UpdateNew, UPDATE); err != nil {
+		return nil, err
+	}
+
+	if err := record(changes.Delete, DELETE); err != nil {
+		return nil, err
+	}
+
+	batches := map[string]*zoneBatch{}
+
+	for _, d := range decided {
+		batch, ok := batches[d.zone.ID]
+		if !ok {
+			batch = &zoneBatch{zone: d.zone}
+			batches[d.zone.ID] = batch
+		}
+
+		switch d.action {
+		case CREATE:
+			batch.creates = append(batch.creates, d.endpoint)
+		case UPDATE:
+			batch.updates = append(batch.updates, d.endpoint)
+		case DELETE:
+			batch.deletes = append(batch.deletes, d.endpoint)
+		}
+	}
+
+	return batches, nil
+}
+
+// chunkZoneBatches flattens the per-zone batches into worker-sized chunks of
+// at most maxChangesPerBatch endpoints each, one action at a time. A
+// maxChangesPerBatch <= 0 means no cap.
+//
+// STACKIT's RecordSetApi does not currently expose a bulk create/update/
+// delete endpoint, so each chunk is still applied one record at a time by
+// the worker pool; capping the chunk size keeps the shape ready to switch to
+// a real bulk call per zone once the API supports it, without changing the
+// aggregation or retry logic again.
+func chunkZoneBatches(zoneBatches map[string]*zoneBatch, maxChangesPerBatch int) []zoneActionBatch {
+	var result []zoneActionBatch
+
+	for _, batch := range zoneBatches {
+		result = append(result, chunkAction(batch.zone, CREATE, batch.creates, maxChangesPerBatch)...)
+		result = append(result, chunkAction(batch.zone, UPDATE, batch.updates, maxChangesPerBatch)...)
+		result = append(result, chunkAction(batch.zone, DELETE, batch.deletes, maxChangesPerBatch)...)
+	}
+
+	return result
+}
+
+func chunkAction(zone Zone, action string, endpoints []*endpoint.Endpoint, maxChangesPerBatch int) []zoneActionBatch {
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	chunkSize := maxChangesPerBatch
+	if chunkSize <= 0 {
+		chunkSize = len(endpoints)
+	}
+
+	var chunks []zoneActionBatch
+
+	for start := 0; start < len(endpoints); start += chunkSize {
+		end := start + chunkSize
+		if end > len(endpoints) {
+			end = len(endpoints)
+		}
+
+		chunks = append(chunks, zoneActionBatch{zone: zone, action: action, changes: endpoints[start:end]})
+	}
+
+	return chunks
+}
+
+// countEndpoints returns the total number of endpoints across all batches,
+// used to size the results channel.
+func countEndpoints(batches []zoneActionBatch) int {
+	total := 0
+	for _, batch := range batches {
+		total += len(batch.changes)
+	}
+
+	return total
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestBuildZoneBatches_DedupesLastWriteWins(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+		},
+	}
+
+	batches, err := buildZoneBatches(changes, zones)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	batch := batches["z1"]
+	if len(batch.creates) != 0 || len(batch.updates) != 1 {
+		t.Fatalf("expected the later update to win, got %+v", batch)
+	}
+
+	if batch.updates[0].Targets[0] != "2.2.2.2" {
+		t.Fatalf("unexpected target: %v", batch.updates[0].Targets)
+	}
+}
+
+func TestBuildZoneBatches_DeleteAlwaysWins(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		},
+		Delete: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A"},
+		},
+	}
+
+	batches, err := buildZoneBatches(changes, zones)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	batch := batches["z1"]
+	if len(batch.creates) != 0 || len(batch.deletes) != 1 {
+		t.Fatalf("expected the delete to win regardless of order, got %+v", batch)
+	}
+}
+
+func TestBuildZoneBatches_NoMatchingZoneReturnsError(t *testing.T) {
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "a.nowhere.test", RecordType: "A"}},
+	}
+
+	if _, err := buildZoneBatches(changes, nil); err == nil {
+		t.Fatal("expected an error for an unmatched zone")
+	}
+}
+
+func TestChunkAction_SplitsIntoCappedChunks(t *testing.T) {
+	zone := Zone{ID: "z1"}
+	endpoints := make([]*endpoint.Endpoint, 5)
+	for i := range endpoints {
+		endpoints[i] = &endpoint.Endpoint{DNSName: "x", RecordType: "A"}
+	}
+
+	chunks := chunkAction(zone, CREATE, endpoints, 2)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+
+	if len(chunks[0].changes) != 2 || len(chunks[2].changes) != 1 {
+		t.Fatalf("unexpected chunk sizes: %d, %d, %d", len(chunks[0].changes), len(chunks[1].changes), len(chunks[2].changes))
+	}
+}
+
+func TestChunkAction_NoCapReturnsSingleChunk(t *testing.T) {
+	zone := Zone{ID: "z1"}
+	endpoints := []*endpoint.Endpoint{{DNSName: "x", RecordType: "A"}}
+
+	chunks := chunkAction(zone, CREATE, endpoints, 0)
+	if len(chunks) != 1 || len(chunks[0].changes) != 1 {
+		t.Fatalf("expected a single uncapped chunk, got %+v", chunks)
+	}
+}
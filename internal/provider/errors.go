@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Classification sentinels a ProviderError's wrapped Err can point to so
+// callers can use errors.Is against a class of failure instead of string
+// matching, e.g. errors.Is(err, ErrRateLimited).
+var (
+	// ErrZoneNotFound means no configured zone matched the endpoint's DNS
+	// name.
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrRateLimited means the backend responded with a 429.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation means the backend rejected the request as invalid
+	// (4xx other than 429) and retrying it unchanged would not help.
+	ErrValidation = errors.New("validation error")
+	// ErrTransient means the failure looks temporary, e.g. a 5xx response
+	// or a network error, and is worth retrying.
+	ErrTransient = errors.New("transient error")
+)
+
+// ProviderError carries the operation context lost when a bare
+// fmt.Errorf or a raw backend error reaches external-dns: which action was
+// attempted, which record and zone it concerned, and, for backend errors,
+// the HTTP status and request ID that would otherwise only show up in logs.
+type ProviderError struct {
+	Action       string
+	DNSName      string
+	RecordType   string
+	ZoneID       string
+	RRSetID      string
+	HTTPStatus   int
+	APIRequestID string
+	Err          error
+}
+
+// Error renders only the context that is actually set, since not every
+// action concerns a single record: a Zones or ListRRSets failure carries no
+// DNSName/RecordType (and, for Zones, no ZoneID either), while
+// create/update/delete failures carry all three.
+func (e *ProviderError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Action)
+
+	if e.RecordType != "" || e.DNSName != "" {
+		fmt.Fprintf(&b, " %s record %q", e.RecordType, e.DNSName)
+	}
+
+	if e.ZoneID != "" {
+		fmt.Fprintf(&b, " in zone %s", e.ZoneID)
+	}
+
+	if e.HTTPStatus != 0 {
+		fmt.Fprintf(&b, " (http status %d", e.HTTPStatus)
+		if e.APIRequestID != "" {
+			fmt.Fprintf(&b, ", request id %s", e.APIRequestID)
+		}
+
+		b.WriteString(")")
+	}
+
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	return b.String()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProviderError_RecordActionIncludesNameAndZone(t *testing.T) {
+	err := &ProviderError{Action: CREATE, DNSName: "a.example.com", RecordType: "A", ZoneID: "z1", Err: errors.New("boom")}
+
+	got := err.Error()
+	if !strings.Contains(got, `A record "a.example.com" in zone z1`) {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+func TestProviderError_ListActionOmitsEmptyFields(t *testing.T) {
+	err := &ProviderError{Action: "list zones", Err: errors.New("boom")}
+
+	got := err.Error()
+	if strings.Contains(got, `""`) || strings.Contains(got, "  ") || strings.Contains(got, "in zone") {
+		t.Fatalf("expected no record/zone placeholders in a zone-listing error, got %q", got)
+	}
+
+	if got != "list zones: boom" {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+func TestProviderError_ListRRSetsActionIncludesZoneOnly(t *testing.T) {
+	err := &ProviderError{Action: "list rrsets", ZoneID: "z1", Err: errors.New("boom")}
+
+	got := err.Error()
+	if got != "list rrsets in zone z1: boom" {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// mockBackend is an in-memory DNSBackend used to exercise Provider's
+// reconciliation, batching, retry and worker-pool logic without HTTP mocks.
+type mockBackend struct {
+	mu     sync.Mutex
+	zones  []Zone
+	rrsets map[string][]RRSet // zoneID -> live RRSets
+	failN  map[string]int     // op -> remaining calls to fail with failErr
+	failOn error
+}
+
+func newMockBackend(zones []Zone, rrsets map[string][]RRSet) *mockBackend {
+	return &mockBackend{zones: zones, rrsets: rrsets, failN: map[string]int{}}
+}
+
+// failNext makes the next n calls to op fail with err.
+func (m *mockBackend) failNext(op string, n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failN[op] = n
+	m.failOn = err
+}
+
+func (m *mockBackend) maybeFail(op string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failN[op] > 0 {
+		m.failN[op]--
+
+		return m.failOn
+	}
+
+	return nil
+}
+
+func (m *mockBackend) Zones(ctx context.Context) ([]Zone, error) {
+	if err := m.maybeFail("zones"); err != nil {
+		return nil, err
+	}
+
+	return m.zones, nil
+}
+
+func (m *mockBackend) ListRRSets(ctx context.Context, zoneID string) ([]RRSet, error) {
+	if err := m.maybeFail("list"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]RRSet, len(m.rrsets[zoneID]))
+	copy(out, m.rrsets[zoneID])
+
+	return out, nil
+}
+
+func (m *mockBackend) CreateRRSet(ctx context.Context, zoneID string, rrSet RRSet) error {
+	if err := m.maybeFail("create"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rrSet.ID = fmt.Sprintf("%s/%s/%s", zoneID, rrSet.Name, rrSet.Type)
+	m.rrsets[zoneID] = append(m.rrsets[zoneID], rrSet)
+
+	return nil
+}
+
+func (m *mockBackend) UpdateRRSet(ctx context.Context, zoneID string, rrSet RRSet) error {
+	if err := m.maybeFail("update"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.rrsets[zoneID] {
+		if existing.ID == rrSet.ID {
+			m.rrsets[zoneID][i] = rrSet
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("rrset %s not found", rrSet.ID)
+}
+
+func (m *mockBackend) DeleteRRSet(ctx context.Context, zoneID string, rrSet RRSet) error {
+	if err := m.maybeFail("delete"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	filtered := m.rrsets[zoneID][:0]
+
+	for _, existing := range m.rrsets[zoneID] {
+		if existing.ID != rrSet.ID {
+			filtered = append(filtered, existing)
+		}
+	}
+
+	m.rrsets[zoneID] = filtered
+
+	return nil
+}
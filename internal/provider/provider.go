@@ -0,0 +1,371 @@
+// Package provider implements the reconciliation, batching, retry and
+// worker-pool logic shared by every DNSBackend. It does not know about any
+// particular DNS API; see internal/stackitprovider/backends/stackit for the
+// STACKIT implementation.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// Provider applies external-dns plan.Changes against any DNSBackend.
+type Provider struct {
+	backend            DNSBackend
+	logger             *zap.Logger
+	dryRun             bool
+	workers            int
+	retryConfig        RetryConfig
+	maxChangesPerBatch int
+	reconcileConfig    ReconcileConfig
+
+	// reconcileMu guards desired and lastFullReconcile, which a full
+	// reconciliation pass both reads and updates; everything else on
+	// Provider is either immutable after construction or already
+	// synchronized through the worker pool's channels.
+	reconcileMu       sync.Mutex
+	desired           map[changeKey]*endpoint.Endpoint
+	lastFullReconcile time.Time
+}
+
+// NewProvider builds a Provider that applies changes through backend.
+func NewProvider(
+	backend DNSBackend,
+	logger *zap.Logger,
+	dryRun bool,
+	workers int,
+	retryConfig RetryConfig,
+	maxChangesPerBatch int,
+	reconcileConfig ReconcileConfig,
+) *Provider {
+	return &Provider{
+		backend:            backend,
+		logger:             logger,
+		dryRun:             dryRun,
+		workers:            workers,
+		retryConfig:        retryConfig,
+		maxChangesPerBatch: maxChangesPerBatch,
+		reconcileConfig:    reconcileConfig,
+		desired:            map[changeKey]*endpoint.Endpoint{},
+	}
+}
+
+// ApplyChanges applies a given set of changes across the backend's zones. Changes are
+// grouped by their resolved zone and deduplicated so that a record touched
+// more than once in the same plan.Changes (e.g. created and then updated
+// again by a later endpoint) only results in a single backend call, then
+// capped into MaxChangesPerBatch-sized chunks per zone before being handed
+// to the worker pool. Errors from individual endpoints are collected rather
+// than swallowed, so external-dns learns about failures instead of silently
+// relying on the next sync to retry them.
+//
+// When reconcileConfig.Mode is ReconcileModeFull, and at least
+// reconcileConfig.Interval has passed since the last one, the batch is
+// additionally true'd up against every zone's live RRSets before being
+// applied, see fullReconcile. Runs in between stay incremental, but still
+// record what they applied as desired state for the next full pass.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	zones, err := p.backend.Zones(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.reconcileConfig.Mode == ReconcileModeFull && p.fullReconcileDue() {
+		return p.fullReconcile(ctx, zones, changes)
+	}
+
+	zoneBatches, err := buildZoneBatches(changes, zones)
+	if err != nil {
+		return err
+	}
+
+	batches := chunkZoneBatches(zoneBatches, p.maxChangesPerBatch)
+
+	if p.reconcileConfig.Mode != ReconcileModeFull {
+		return p.handleRRSetWithWorkers(ctx, batches)
+	}
+
+	// An incremental run between two full passes still records what each
+	// cleanly applied zone now holds, so the next full pass neither recreates
+	// a record deleted here nor misses drift on one created here.
+	results := p.runWorkers(ctx, batches)
+	for _, result := range results {
+		if result.err != nil {
+			delete(zoneBatches, result.zoneID)
+		}
+	}
+
+	p.rememberDesired(zoneBatches)
+
+	return aggregateWorkerErrors(results)
+}
+
+// createRRSet creates a new record set for the given endpoint in zone.
+func (p *Provider) createRRSet(ctx context.Context, change *endpoint.Endpoint, zone Zone) error {
+	normalizeChange(change)
+
+	logFields := getLogFields(change, CREATE, zone.ID)
+	p.logger.Info("create record set", logFields...)
+
+	if p.dryRun {
+		p.logger.Debug("dry run, skipping", logFields...)
+
+		return nil
+	}
+
+	rrSet := RRSet{Name: change.DNSName, Type: change.RecordType, Records: change.Targets}
+
+	err := withRetry(ctx, p.retryConfig, p.logger, "create", func() error {
+		return p.backend.CreateRRSet(ctx, zone.ID, rrSet)
+	})
+	if err != nil {
+		p.logger.Error("error creating record set", zap.Error(err))
+
+		return err
+	}
+
+	p.logger.Info("create record set successfully", logFields...)
+
+	return nil
+}
+
+// updateRRSet overrides the contents of the record set matching change in zone.
+func (p *Provider) updateRRSet(ctx context.Context, change *endpoint.Endpoint, zone Zone) error {
+	normalizeChange(change)
+
+	existing, err := p.findRRSet(ctx, zone, change, UPDATE)
+	if err != nil {
+		return err
+	}
+
+	logFields := getLogFields(change, UPDATE, zone.ID)
+	p.logger.Info("update record set", logFields...)
+
+	if p.dryRun {
+		p.logger.Debug("dry run, skipping", logFields...)
+
+		return nil
+	}
+
+	rrSet := RRSet{ID: existing.ID, Name: change.DNSName, Type: change.RecordType, Records: change.Targets}
+
+	err = withRetry(ctx, p.retryConfig, p.logger, "update", func() error {
+		return p.backend.UpdateRRSet(ctx, zone.ID, rrSet)
+	})
+	if err != nil {
+		p.logger.Error("error updating record set", zap.Error(err))
+
+		return err
+	}
+
+	p.logger.Info("update record set successfully", logFields...)
+
+	return nil
+}
+
+// deleteRRSet deletes the record set matching change in zone.
+func (p *Provider) deleteRRSet(ctx context.Context, change *endpoint.Endpoint, zone Zone) error {
+	normalizeChange(change)
+
+	existing, err := p.findRRSet(ctx, zone, change, DELETE)
+	if err != nil {
+		return err
+	}
+
+	logFields := getLogFields(change, DELETE, zone.ID)
+	p.logger.Info("delete record set", logFields...)
+
+	if p.dryRun {
+		p.logger.Debug("dry run, skipping", logFields...)
+
+		return nil
+	}
+
+	err = withRetry(ctx, p.retryConfig, p.logger, "delete", func() error {
+		return p.backend.DeleteRRSet(ctx, zone.ID, existing)
+	})
+	if err != nil {
+		p.logger.Error("error deleting record set", zap.Error(err))
+
+		return err
+	}
+
+	p.logger.Info("delete record set successfully", logFields...)
+
+	return nil
+}
+
+// normalizeChange strips the trailing dot external-dns puts on DNSName
+// (FQDN form) so it matches the bare names the STACKIT API returns and
+// expects. This has to run before change.DNSName is used to build an RRSet
+// or matched against a live one in findRRSet, or every update/delete would
+// fail to find its record.
+func normalizeChange(change *endpoint.Endpoint) {
+	change.DNSName = strings.TrimSuffix(change.DNSName, ".")
+}
+
+// findRRSet looks up the live RRSet matching change's name and type in zone,
+// which update and delete need to address the record by its backend ID.
+func (p *Provider) findRRSet(ctx context.Context, zone Zone, change *endpoint.Endpoint, action string) (RRSet, error) {
+	live, err := p.backend.ListRRSets(ctx, zone.ID)
+	if err != nil {
+		return RRSet{}, err
+	}
+
+	for _, rrSet := range live {
+		if rrSet.Name == change.DNSName && rrSet.Type == change.RecordType {
+			return rrSet, nil
+		}
+	}
+
+	return RRSet{}, &ProviderError{
+		Action:     action,
+		DNSName:    change.DNSName,
+		RecordType: change.RecordType,
+		ZoneID:     zone.ID,
+		Err:        fmt.Errorf("no matching rrset found"),
+	}
+}
+
+// getLogFields builds the zap fields shared by the create/update/delete log
+// lines for change.
+func getLogFields(change *endpoint.Endpoint, action, zoneID string) []zap.Field {
+	return []zap.Field{
+		zap.String("action", action),
+		zap.String("dnsName", change.DNSName),
+		zap.String("recordType", change.RecordType),
+		zap.String("zoneID", zoneID),
+	}
+}
+
+// workerResult carries the outcome of a single endpoint change back from a
+// changeWorker so handleRRSetWithWorkers can aggregate failures instead of
+// discarding them.
+type workerResult struct {
+	action  string
+	zoneID  string
+	dnsName string
+	err     error
+}
+
+// handleRRSetWithWorkers hands the given per-zone batches to workers to
+// optimize speed and returns an aggregated error, grouped by action and
+// zone, for every endpoint that failed. Workers iterate over zone batches
+// rather than individual endpoints, so a zone is only resolved once; the
+// changes within one batch are applied in order, but different batches,
+// including those of the same zone, may run concurrently on different
+// workers.
+func (p *Provider) handleRRSetWithWorkers(ctx context.Context, batches []zoneActionBatch) error {
+	return aggregateWorkerErrors(p.runWorkers(ctx, batches))
+}
+
+// runWorkers hands the given per-zone batches to workers and collects every
+// endpoint's outcome, letting callers that need more than a single
+// aggregated error (e.g. fullReconcile, which must know which zones
+// succeeded) inspect results themselves.
+func (p *Provider) runWorkers(ctx context.Context, batches []zoneActionBatch) []workerResult {
+	workerChannel := make(chan zoneActionBatch, len(batches))
+	resultsChannel := make(chan workerResult, countEndpoints(batches))
+	wg := new(sync.WaitGroup)
+
+	// create workers
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go p.changeWorker(ctx, wg, workerChannel, resultsChannel)
+	}
+
+	for _, batch := range batches {
+		workerChannel <- batch
+	}
+
+	close(workerChannel)
+	wg.Wait()
+	close(resultsChannel)
+
+	results := make([]workerResult, 0, len(resultsChannel))
+	for result := range resultsChannel {
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// changeWorker is a worker that handles zone batches passed by a channel and
+// reports the per-endpoint outcome on results.
+func (p *Provider) changeWorker(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	batches chan zoneActionBatch,
+	results chan<- workerResult,
+) {
+	defer wg.Done()
+
+	for batch := range batches {
+		for _, change := range batch.changes {
+			var err error
+
+			switch batch.action {
+			case CREATE:
+				err = p.createRRSet(ctx, change, batch.zone)
+			case UPDATE:
+				err = p.updateRRSet(ctx, change, batch.zone)
+			case DELETE:
+				err = p.deleteRRSet(ctx, change, batch.zone)
+			}
+
+			results <- workerResult{
+				action:  batch.action,
+				zoneID:  batch.zone.ID,
+				dnsName: change.DNSName,
+				err:     err,
+			}
+		}
+	}
+
+	p.logger.Debug("change worker finished")
+}
+
+// aggregateWorkerErrors groups the failed results by action and zone and
+// joins them into a single error so ApplyChanges can surface every failure
+// to external-dns instead of just the first one.
+func aggregateWorkerErrors(results []workerResult) error {
+	type groupKey struct {
+		action string
+		zoneID string
+	}
+
+	var order []groupKey
+	grouped := map[groupKey][]error{}
+
+	for _, result := range results {
+		if result.err == nil {
+			continue
+		}
+
+		key := groupKey{action: result.action, zoneID: result.zoneID}
+		if _, ok := grouped[key]; !ok {
+			order = append(order, key)
+		}
+
+		grouped[key] = append(grouped[key], fmt.Errorf("%s: %w", result.dnsName, result.err))
+	}
+
+	if len(order) == 0 {
+		return nil
+	}
+
+	groupErrs := make([]error, 0, len(order))
+	for _, key := range order {
+		groupErrs = append(groupErrs, fmt.Errorf("%s failed for zone %s: %w", key.action, key.zoneID, errors.Join(grouped[key]...)))
+	}
+
+	return errors.Join(groupErrs...)
+}
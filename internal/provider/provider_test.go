@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func newTestProvider(backend DNSBackend, mode ReconcileMode) *Provider {
+	return NewProvider(backend, zap.NewNop(), false, 2, RetryConfig{MaxAttempts: 1}, 0, ReconcileConfig{Mode: mode})
+}
+
+func TestApplyChanges_CreateUpdateDelete(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	backend := newMockBackend(zones, map[string][]RRSet{
+		"z1": {{ID: "z1/old.example.com/A", Name: "old.example.com", Type: "A", Records: []string{"1.1.1.1"}}},
+	})
+	p := newTestProvider(backend, ReconcileModeIncremental)
+
+	changes := &plan.Changes{
+		Create:    []*endpoint.Endpoint{{DNSName: "new.example.com", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}}},
+		UpdateNew: []*endpoint.Endpoint{{DNSName: "old.example.com", RecordType: "A", Targets: endpoint.Targets{"3.3.3.3"}}},
+		Delete:    []*endpoint.Endpoint{{DNSName: "old.example.com", RecordType: "A"}},
+	}
+
+	// old.example.com is both updated and deleted in the same plan; the
+	// delete wins, so only the create should be left standing.
+	if err := p.ApplyChanges(context.Background(), changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	live := backend.rrsets["z1"]
+	if len(live) != 1 || live[0].Name != "new.example.com" {
+		t.Fatalf("unexpected live state: %+v", live)
+	}
+}
+
+func TestApplyChanges_AggregatesWorkerErrors(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	backend := newMockBackend(zones, map[string][]RRSet{"z1": nil})
+	backend.failNext("create", 1, &ProviderError{Action: CREATE, Err: ErrValidation})
+	p := newTestProvider(backend, ReconcileModeIncremental)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "bad.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}}},
+	}
+
+	err := p.ApplyChanges(context.Background(), changes)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestApplyChanges_DryRunMakesNoBackendChanges(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	backend := newMockBackend(zones, map[string][]RRSet{"z1": nil})
+	p := NewProvider(backend, zap.NewNop(), true, 1, RetryConfig{MaxAttempts: 1}, 0, ReconcileConfig{Mode: ReconcileModeIncremental})
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "new.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}}},
+	}
+
+	if err := p.ApplyChanges(context.Background(), changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(backend.rrsets["z1"]) != 0 {
+		t.Fatalf("expected dry run to leave backend state untouched, got %+v", backend.rrsets["z1"])
+	}
+}
+
+func TestApplyChanges_FullReconcileWrapsListRRSetsErrorAsProviderError(t *testing.T) {
+	zones := []Zone{{ID: "z1", Name: "example.com"}}
+	backend := newMockBackend(zones, map[string][]RRSet{"z1": nil})
+	backend.failNext("list", 1, errors.New("boom"))
+	p := newTestProvider(backend, ReconcileModeFull)
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{})
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("expected a *ProviderError, got %v", err)
+	}
+
+	if providerErr.Action != "list rrsets" || providerErr.ZoneID != "z1" {
+		t.Fatalf("unexpected provider error: %+v", providerErr)
+	}
+}
+
+func TestApplyChanges_FullReconcileRemembersZonesThatSucceededDespiteAnotherFailing(t *testing.T) {
+	zones := []Zone{
+		{ID: "z1", Name: "one.com"},
+		{ID: "z2", Name: "two.com"},
+	}
+	backend := newMockBackend(zones, map[string][]RRSet{"z1": nil, "z2": nil})
+	backend.failNext("create", 1, errors.New("boom"))
+	p := newTestProvider(backend, ReconcileModeFull)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "a.one.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+			{DNSName: "a.two.com", RecordType: "A", Targets: endpoint.Targets{"2.2.2.2"}},
+		},
+	}
+
+	err := p.ApplyChanges(context.Background(), changes)
+	if err == nil {
+		t.Fatal("expected the single failing create to surface an error")
+	}
+
+	// Exactly one of the two zones applied cleanly; its desired state must
+	// still be remembered even though the pass as a whole failed, and the
+	// timestamp must still advance so the next run isn't forced full again
+	// for no reason.
+	if len(p.desired) != 1 {
+		t.Fatalf("expected the zone that succeeded to be remembered, got %+v", p.desired)
+	}
+
+	if p.lastFullReconcile.IsZero() {
+		t.Fatal("expected lastFullReconcile to advance even though one zone failed")
+	}
+}
+
+func TestApplyChanges_FullReconcileRecreatesDriftInZoneWithNoPendingChanges(t *testing.T) {
+	zones := []Zone{
+		{ID: "z1", Name: "example.com"},
+		{ID: "z2", Name: "other.com"},
+	}
+	backend := newMockBackend(zones, map[string][]RRSet{"z1": nil, "z2": nil})
+	p := newTestProvider(backend, ReconcileModeFull)
+
+	// First run applies and remembers a record in z2.
+	first := &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "a.other.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}}},
+	}
+	if err := p.ApplyChanges(context.Background(), first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate a hand-edit that external-dns's own diff never sees: the
+	// record disappears from z2 with no pending change for that zone.
+	backend.rrsets["z2"] = nil
+
+	if err := p.ApplyChanges(context.Background(), &plan.Changes{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	live := backend.rrsets["z2"]
+	if len(live) != 1 || live[0].Name != "a.other.com" {
+		t.Fatalf("expected the drifted record in an untouched zone to be recreated, got %+v", live)
+	}
+}
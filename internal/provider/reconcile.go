@@ -0,0 +1,354 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// ReconcileMode controls how ApplyChanges reconciles a zone's RRSets with
+// the desired state.
+type ReconcileMode string
+
+const (
+	// ReconcileModeIncremental applies only the Create/UpdateNew/Delete
+	// diff handed in by external-dns for this run. This is the provider's
+	// historic behaviour and remains the default.
+	ReconcileModeIncremental ReconcileMode = "incremental"
+	// ReconcileModeFull additionally lists the live RRSets of every zone
+	// the backend manages and true's up the desired state against them
+	// before applying, so drift left behind by a partially failed run or a
+	// hand-edit at the backend gets repaired instead of persisting until
+	// external-dns's own diff happens to notice. See ReconcileConfig.
+	ReconcileModeFull ReconcileMode = "full"
+)
+
+// ReconcileConfig controls how, how often, and how safely ApplyChanges runs
+// a full reconciliation instead of just applying the incremental diff.
+type ReconcileConfig struct {
+	// Mode selects incremental or full reconciliation.
+	Mode ReconcileMode
+	// Interval is the minimum time between two full reconciliation passes;
+	// runs requested in between still apply, but stay incremental. <= 0
+	// means every ApplyChanges call runs full reconciliation when Mode is
+	// ReconcileModeFull. This is what a --reconcile-interval flag should be
+	// wired to by the caller that owns flag parsing.
+	Interval time.Duration
+	// TXTOwnerID restricts orphan cleanup (see reconcileZoneBatch) to live
+	// records whose TXT registry ownership marker names this owner ID.
+	// Empty disables the owner ID check: every live record that has a TXT
+	// registry ownership marker, whatever owner it names, is treated as
+	// ours to clean up, which is only safe if this backend's zones aren't
+	// shared with another external-dns instance. Records without a marker
+	// are never cleaned up either way.
+	TXTOwnerID string
+}
+
+// txtOwnerPrefix is prepended to a managed record's name to find its TXT
+// registry ownership marker, mirroring external-dns's default TXT registry
+// naming (the "prefix" naming scheme, e.g. "external-dns-www.example.com").
+const txtOwnerPrefix = "external-dns-"
+
+// fullReconcileDue reports whether enough time has passed since the last
+// full reconciliation for another one to run, per reconcileConfig.Interval.
+func (p *Provider) fullReconcileDue() bool {
+	if p.reconcileConfig.Interval <= 0 {
+		return true
+	}
+
+	p.reconcileMu.Lock()
+	defer p.reconcileMu.Unlock()
+
+	return time.Since(p.lastFullReconcile) >= p.reconcileConfig.Interval
+}
+
+// fullReconcile lists the live RRSets of every zone the backend manages,
+// including ones with no pending change this cycle, and true's up both the
+// current diff and everything previously applied against them:
+//   - a Create for a (name, type) tuple that already exists live is
+//     promoted to an Update instead of risking a duplicate-record error
+//     from the backend, and a Delete for a tuple that is no longer live is
+//     dropped instead of failing on a record that is already gone (as
+//     before);
+//   - a record this provider applied on an earlier run but that is missing
+//     or has drifted on a zone with no pending diff this cycle is
+//     recreated or updated, since external-dns's own diff never saw drift
+//     it didn't know to look for;
+//   - a live record that carries this instance's TXT ownership marker but
+//     is no longer desired is deleted, so records left behind by a removed
+//     source or a partially failed run don't persist forever. Records
+//     without that marker, or naming a different TXTOwnerID, are never
+//     touched.
+//
+// Desired state is remembered per zone: a zone whose batch applied without
+// error has its state recorded even if another zone in the same pass failed,
+// so one flaky zone can't stall self-heal bookkeeping everywhere else.
+func (p *Provider) fullReconcile(ctx context.Context, zones []Zone, changes *plan.Changes) error {
+	zoneBatches, err := buildZoneBatches(changes, zones)
+	if err != nil {
+		return err
+	}
+
+	for _, zone := range zones {
+		if _, ok := zoneBatches[zone.ID]; !ok {
+			zoneBatches[zone.ID] = &zoneBatch{zone: zone}
+		}
+	}
+
+	for zoneID, batch := range zoneBatches {
+		live, err := p.backend.ListRRSets(ctx, zoneID)
+		if err != nil {
+			return &ProviderError{Action: "list rrsets", ZoneID: zoneID, Err: err}
+		}
+
+		p.reconcileZoneBatch(batch, live)
+	}
+
+	batches := chunkZoneBatches(zoneBatches, p.maxChangesPerBatch)
+	results := p.runWorkers(ctx, batches)
+
+	// A zone whose own changes all applied cleanly gets its desired state
+	// remembered even if some other zone in the same pass failed, so one
+	// flaky zone doesn't block self-heal bookkeeping for every zone that
+	// succeeded alongside it. A full pass was still attempted regardless of
+	// outcome, so lastFullReconcile always advances.
+	failedZones := map[string]bool{}
+	for _, result := range results {
+		if result.err != nil {
+			failedZones[result.zoneID] = true
+		}
+	}
+
+	succeeded := map[string]*zoneBatch{}
+	for zoneID, batch := range zoneBatches {
+		if !failedZones[zoneID] {
+			succeeded[zoneID] = batch
+		}
+	}
+
+	p.rememberDesired(succeeded)
+
+	p.reconcileMu.Lock()
+	p.lastFullReconcile = time.Now()
+	p.reconcileMu.Unlock()
+
+	return aggregateWorkerErrors(results)
+}
+
+// reconcileZoneBatch true's up batch's planned creates, updates and deletes
+// against the zone's live RRSets and against everything this provider
+// previously applied to the zone, mutating batch in place. Creates and
+// deletes are normalized (see normalizeChange) before being matched against
+// liveByKey, which is keyed off the backend's bare rrSet.Name: without that,
+// an endpoint whose DNSName still carries external-dns's trailing FQDN dot
+// would never match a live record, and every cycle would keep trying to
+// recreate a record that already exists.
+func (p *Provider) reconcileZoneBatch(batch *zoneBatch, live []RRSet) {
+	liveByKey := make(map[changeKey]RRSet, len(live))
+	ownerMarkers := map[string]RRSet{} // managed record name -> its owned TXT marker
+
+	for _, rrSet := range live {
+		liveByKey[changeKey{zoneID: batch.zone.ID, name: rrSet.Name, rtype: rrSet.Type}] = rrSet
+
+		if rrSet.Type == "TXT" && strings.HasPrefix(rrSet.Name, txtOwnerPrefix) && p.ownsTXT(rrSet) {
+			ownerMarkers[strings.TrimPrefix(rrSet.Name, txtOwnerPrefix)] = rrSet
+		}
+	}
+
+	var stillCreate []*endpoint.Endpoint
+
+	for _, ep := range batch.creates {
+		normalizeChange(ep)
+
+		key := changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}
+		if _, ok := liveByKey[key]; ok {
+			batch.updates = append(batch.updates, ep)
+
+			continue
+		}
+
+		stillCreate = append(stillCreate, ep)
+	}
+
+	batch.creates = stillCreate
+
+	var stillDelete []*endpoint.Endpoint
+
+	for _, ep := range batch.deletes {
+		normalizeChange(ep)
+
+		key := changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}
+		if _, ok := liveByKey[key]; !ok {
+			continue
+		}
+
+		stillDelete = append(stillDelete, ep)
+	}
+
+	batch.deletes = stillDelete
+
+	decided := map[changeKey]bool{}
+	for _, ep := range batch.creates {
+		decided[changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}] = true
+	}
+
+	for _, ep := range batch.updates {
+		decided[changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}] = true
+	}
+
+	for _, ep := range batch.deletes {
+		decided[changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}] = true
+	}
+
+	for key, ep := range p.desiredForZone(batch.zone.ID) {
+		if decided[key] {
+			continue
+		}
+
+		rrSet, ok := liveByKey[key]
+		switch {
+		case !ok:
+			batch.creates = append(batch.creates, ep)
+			decided[key] = true
+		case !sameRecords(rrSet.Records, ep.Targets):
+			batch.updates = append(batch.updates, ep)
+			decided[key] = true
+		}
+	}
+
+	// Anything live, owned by our TXT marker and not already covered by a
+	// create/update/delete above (by its own (name, type), not just its
+	// name, so a stale record of a different type at the same name still
+	// gets caught) is no longer desired and gets deleted.
+	for key, rrSet := range liveByKey {
+		if rrSet.Type == "TXT" || decided[key] {
+			continue
+		}
+
+		if _, owned := ownerMarkers[key.name]; !owned {
+			continue
+		}
+
+		batch.deletes = append(batch.deletes, &endpoint.Endpoint{DNSName: rrSet.Name, RecordType: rrSet.Type})
+		decided[key] = true
+	}
+
+	// A TXT ownership marker whose managed record no longer exists (deleted
+	// just above, deleted explicitly by the diff, or already absent) doesn't
+	// describe anything anymore and would otherwise persist as registry
+	// cruft forever; clean it up alongside the record it used to describe.
+	survivingNames := map[string]bool{}
+
+	for _, ep := range batch.creates {
+		survivingNames[ep.DNSName] = true
+	}
+
+	for _, ep := range batch.updates {
+		survivingNames[ep.DNSName] = true
+	}
+
+	for key, rrSet := range liveByKey {
+		if rrSet.Type != "TXT" && !decided[key] {
+			survivingNames[key.name] = true
+		}
+	}
+
+	for name, marker := range ownerMarkers {
+		if survivingNames[name] {
+			continue
+		}
+
+		key := changeKey{zoneID: batch.zone.ID, name: marker.Name, rtype: marker.Type}
+		if decided[key] {
+			continue
+		}
+
+		batch.deletes = append(batch.deletes, &endpoint.Endpoint{DNSName: marker.Name, RecordType: marker.Type})
+		decided[key] = true
+	}
+}
+
+// ownsTXT reports whether rrSet's content carries this provider's
+// TXTOwnerID ownership marker, following external-dns's TXT registry
+// convention ("heritage=external-dns,external-dns/owner=<id>"). An empty
+// TXTOwnerID disables the check so every TXT marker counts as owned.
+func (p *Provider) ownsTXT(rrSet RRSet) bool {
+	if p.reconcileConfig.TXTOwnerID == "" {
+		return true
+	}
+
+	marker := "external-dns/owner=" + p.reconcileConfig.TXTOwnerID
+
+	for _, record := range rrSet.Records {
+		if strings.Contains(record, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// desiredForZone returns the subset of everything this provider has
+// previously applied that belongs to zoneID.
+func (p *Provider) desiredForZone(zoneID string) map[changeKey]*endpoint.Endpoint {
+	p.reconcileMu.Lock()
+	defer p.reconcileMu.Unlock()
+
+	out := make(map[changeKey]*endpoint.Endpoint, len(p.desired))
+
+	for key, ep := range p.desired {
+		if key.zoneID == zoneID {
+			out[key] = ep
+		}
+	}
+
+	return out
+}
+
+// rememberDesired records zoneBatches' final creates and updates as the new
+// desired state for their (zone, name, type) tuples, and forgets tuples that
+// were deleted, so the next full reconciliation can recognize drift even on
+// a zone with no pending diff of its own.
+func (p *Provider) rememberDesired(zoneBatches map[string]*zoneBatch) {
+	p.reconcileMu.Lock()
+	defer p.reconcileMu.Unlock()
+
+	for _, batch := range zoneBatches {
+		for _, ep := range batch.creates {
+			p.desired[changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}] = ep
+		}
+
+		for _, ep := range batch.updates {
+			p.desired[changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType}] = ep
+		}
+
+		for _, ep := range batch.deletes {
+			delete(p.desired, changeKey{zoneID: batch.zone.ID, name: ep.DNSName, rtype: ep.RecordType})
+		}
+	}
+}
+
+// sameRecords reports whether a and b contain the same record values,
+// ignoring order.
+func sameRecords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string(nil), a...)
+	sortedB := append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
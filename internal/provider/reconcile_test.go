@@ -0,0 +1,232 @@
+package provider
+
+import (
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestReconcileZoneBatch_PromotesCreateToUpdateWhenLive(t *testing.T) {
+	p := &Provider{desired: map[changeKey]*endpoint.Endpoint{}}
+	batch := &zoneBatch{
+		zone: Zone{ID: "z1"},
+		creates: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		},
+	}
+	live := []RRSet{{Name: "a.example.com", Type: "A", Records: []string{"9.9.9.9"}}}
+
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.creates) != 0 {
+		t.Fatalf("expected the create to be promoted, got %+v", batch.creates)
+	}
+
+	if len(batch.updates) != 1 {
+		t.Fatalf("expected one update, got %+v", batch.updates)
+	}
+}
+
+func TestReconcileZoneBatch_MatchesLiveRecordDespiteTrailingDot(t *testing.T) {
+	p := &Provider{desired: map[changeKey]*endpoint.Endpoint{}}
+	batch := &zoneBatch{
+		zone: Zone{ID: "z1"},
+		creates: []*endpoint.Endpoint{
+			{DNSName: "a.example.com.", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		},
+		deletes: []*endpoint.Endpoint{
+			{DNSName: "b.example.com.", RecordType: "A"},
+		},
+	}
+	live := []RRSet{
+		{Name: "a.example.com", Type: "A", Records: []string{"9.9.9.9"}},
+		{Name: "b.example.com", Type: "A", Records: []string{"1.1.1.1"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.creates) != 0 {
+		t.Fatalf("expected the create to be promoted to an update despite the trailing dot, got %+v", batch.creates)
+	}
+
+	if len(batch.updates) != 1 {
+		t.Fatalf("expected one update, got %+v", batch.updates)
+	}
+
+	if len(batch.deletes) != 1 {
+		t.Fatalf("expected the delete for the live record to still apply despite the trailing dot, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_DropsDeleteWhenAlreadyGone(t *testing.T) {
+	p := &Provider{desired: map[changeKey]*endpoint.Endpoint{}}
+	batch := &zoneBatch{
+		zone:    Zone{ID: "z1"},
+		deletes: []*endpoint.Endpoint{{DNSName: "a.example.com", RecordType: "A"}},
+	}
+
+	p.reconcileZoneBatch(batch, nil)
+
+	if len(batch.deletes) != 0 {
+		t.Fatalf("expected the delete to be dropped, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_RecreatesAndUpdatesDriftedDesiredRecord(t *testing.T) {
+	zone := Zone{ID: "z1"}
+	desiredEp := &endpoint.Endpoint{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}}
+	p := &Provider{
+		desired: map[changeKey]*endpoint.Endpoint{
+			{zoneID: "z1", name: "a.example.com", rtype: "A"}: desiredEp,
+		},
+	}
+
+	// Nothing pending this cycle and the record is missing entirely: it
+	// gets recreated even though the zone had no diff of its own.
+	batch := &zoneBatch{zone: zone}
+	p.reconcileZoneBatch(batch, nil)
+
+	if len(batch.creates) != 1 {
+		t.Fatalf("expected the missing record to be recreated, got %+v", batch)
+	}
+
+	// The record is present but its content drifted: it gets updated
+	// instead.
+	batch = &zoneBatch{zone: zone}
+	live := []RRSet{{Name: "a.example.com", Type: "A", Records: []string{"2.2.2.2"}}}
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.updates) != 1 {
+		t.Fatalf("expected the drifted record to be updated, got %+v", batch)
+	}
+}
+
+func TestReconcileZoneBatch_DeletesOrphanedOwnedRecordAndItsTXTMarker(t *testing.T) {
+	p := &Provider{
+		desired:         map[changeKey]*endpoint.Endpoint{},
+		reconcileConfig: ReconcileConfig{TXTOwnerID: "me"},
+	}
+	batch := &zoneBatch{zone: Zone{ID: "z1"}}
+	live := []RRSet{
+		{Name: "a.example.com", Type: "A", Records: []string{"1.1.1.1"}},
+		{Name: "external-dns-a.example.com", Type: "TXT", Records: []string{"heritage=external-dns,external-dns/owner=me"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	deleted := map[string]bool{}
+	for _, ep := range batch.deletes {
+		deleted[ep.DNSName+"/"+ep.RecordType] = true
+	}
+
+	if len(batch.deletes) != 2 || !deleted["a.example.com/A"] || !deleted["external-dns-a.example.com/TXT"] {
+		t.Fatalf("expected the orphaned record and its now-meaningless TXT marker to both be deleted, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_DoesNotDuplicateAnExplicitDelete(t *testing.T) {
+	p := &Provider{
+		desired:         map[changeKey]*endpoint.Endpoint{},
+		reconcileConfig: ReconcileConfig{TXTOwnerID: "me"},
+	}
+	batch := &zoneBatch{
+		zone:    Zone{ID: "z1"},
+		deletes: []*endpoint.Endpoint{{DNSName: "a.example.com", RecordType: "A"}},
+	}
+	live := []RRSet{
+		{Name: "a.example.com", Type: "A", Records: []string{"1.1.1.1"}},
+		{Name: "external-dns-a.example.com", Type: "TXT", Records: []string{"heritage=external-dns,external-dns/owner=me"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	aDeletes := 0
+	txtDeletes := 0
+
+	for _, ep := range batch.deletes {
+		switch ep.RecordType {
+		case "A":
+			aDeletes++
+		case "TXT":
+			txtDeletes++
+		}
+	}
+
+	if aDeletes != 1 {
+		t.Fatalf("expected the record already planned for deletion to be deleted only once, got %+v", batch.deletes)
+	}
+
+	if txtDeletes != 1 {
+		t.Fatalf("expected the now-orphaned TXT marker to be cleaned up too, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_CleansUpOrphanOfADifferentTypeAtTheSameName(t *testing.T) {
+	p := &Provider{
+		desired:         map[changeKey]*endpoint.Endpoint{},
+		reconcileConfig: ReconcileConfig{TXTOwnerID: "me"},
+	}
+	batch := &zoneBatch{
+		zone: Zone{ID: "z1"},
+		creates: []*endpoint.Endpoint{
+			{DNSName: "a.example.com", RecordType: "A", Targets: endpoint.Targets{"1.1.1.1"}},
+		},
+	}
+	live := []RRSet{
+		{Name: "a.example.com", Type: "AAAA", Records: []string{"::1"}},
+		{Name: "external-dns-a.example.com", Type: "TXT", Records: []string{"heritage=external-dns,external-dns/owner=me"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.deletes) != 1 || batch.deletes[0].RecordType != "AAAA" {
+		t.Fatalf("expected the orphaned AAAA record to be deleted even though A at the same name is still desired, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_CleansUpDanglingTXTMarkerWithNoManagedRecordLeft(t *testing.T) {
+	p := &Provider{
+		desired:         map[changeKey]*endpoint.Endpoint{},
+		reconcileConfig: ReconcileConfig{TXTOwnerID: "me"},
+	}
+	batch := &zoneBatch{zone: Zone{ID: "z1"}}
+	// The A record itself is already gone (removed by hand or a previous
+	// cycle); only its TXT ownership marker is still live.
+	live := []RRSet{
+		{Name: "external-dns-a.example.com", Type: "TXT", Records: []string{"heritage=external-dns,external-dns/owner=me"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.deletes) != 1 || batch.deletes[0].DNSName != "external-dns-a.example.com" || batch.deletes[0].RecordType != "TXT" {
+		t.Fatalf("expected the dangling TXT marker to be deleted, got %+v", batch.deletes)
+	}
+}
+
+func TestReconcileZoneBatch_LeavesRecordOwnedByAnotherInstanceAlone(t *testing.T) {
+	p := &Provider{
+		desired:         map[changeKey]*endpoint.Endpoint{},
+		reconcileConfig: ReconcileConfig{TXTOwnerID: "me"},
+	}
+	batch := &zoneBatch{zone: Zone{ID: "z1"}}
+	live := []RRSet{
+		{Name: "a.example.com", Type: "A", Records: []string{"1.1.1.1"}},
+		{Name: "external-dns-a.example.com", Type: "TXT", Records: []string{"heritage=external-dns,external-dns/owner=someone-else"}},
+	}
+
+	p.reconcileZoneBatch(batch, live)
+
+	if len(batch.deletes) != 0 {
+		t.Fatalf("expected a record owned by a different TXTOwnerID to be left alone, got %+v", batch.deletes)
+	}
+}
+
+func TestSameRecords(t *testing.T) {
+	if !sameRecords([]string{"a", "b"}, []string{"b", "a"}) {
+		t.Fatal("expected order-independent equality")
+	}
+
+	if sameRecords([]string{"a"}, []string{"a", "b"}) {
+		t.Fatal("expected different lengths to be unequal")
+	}
+}
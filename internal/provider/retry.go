@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryConfig controls the exponential-backoff retry applied around a
+// backend's Create/Update/DeleteRRSet calls.
+type RetryConfig struct {
+	// InitialDelay is the delay before the first retry attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts once the exponential
+	// backoff would otherwise exceed it.
+	MaxDelay time.Duration
+	// MaxAttempts is the total number of attempts, including the first
+	// one. A value <= 1 disables retrying.
+	MaxAttempts int
+	// Jitter is the fraction (0-1) of the computed delay that is randomly
+	// added or subtracted to avoid thundering-herd retries.
+	Jitter float64
+}
+
+// DefaultRetryConfig returns the retry behaviour used when the webhook is
+// not configured with custom values.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		MaxAttempts:  5,
+		Jitter:       0.2,
+	}
+}
+
+// isRetryable reports whether err is classified as worth retrying, i.e. a
+// ProviderError wrapping ErrRateLimited or ErrTransient. A ProviderError
+// wrapping ErrValidation or ErrZoneNotFound, or any other error, is not.
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTransient)
+}
+
+// withRetry runs fn, retrying with exponential backoff and jitter as long as
+// fn keeps returning an error classified as retryable by isRetryable, up to
+// cfg.MaxAttempts, and aborts early if ctx is done between attempts.
+func withRetry(ctx context.Context, cfg RetryConfig, logger *zap.Logger, op string, fn func() error) error {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	delay := cfg.InitialDelay
+
+	var lastErr error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+
+		if !isRetryable(err) {
+			return err
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+
+		wait := addJitter(delay, cfg.Jitter)
+		logger.Warn("retrying after transient backend error",
+			zap.String("op", op),
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > cfg.MaxDelay {
+			delay = cfg.MaxDelay
+		}
+	}
+
+	return lastErr
+}
+
+// addJitter randomly shifts delay by up to +/- fraction of itself.
+func addJitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return delay
+	}
+
+	jitter := float64(delay) * fraction
+	offset := (rand.Float64()*2 - 1) * jitter
+
+	return delay + time.Duration(offset)
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWithRetry_SucceedsWithoutRetry(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxAttempts: 3}
+	calls := 0
+
+	err := withRetry(context.Background(), cfg, zap.NewNop(), "op", func() error {
+		calls++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestWithRetry_RetriesTransientUntilSuccess(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxAttempts: 5}
+	calls := 0
+	transientErr := &ProviderError{Action: "create", Err: ErrTransient}
+
+	err := withRetry(context.Background(), cfg, zap.NewNop(), "op", func() error {
+		calls++
+		if calls < 3 {
+			return transientErr
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxAttempts: 3}
+	calls := 0
+	transientErr := &ProviderError{Action: "create", Err: ErrTransient}
+
+	err := withRetry(context.Background(), cfg, zap.NewNop(), "op", func() error {
+		calls++
+
+		return transientErr
+	})
+	if !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected ErrTransient, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestWithRetry_DoesNotRetryValidationErrors(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxAttempts: 5}
+	calls := 0
+	validationErr := &ProviderError{Action: "create", Err: ErrValidation}
+
+	err := withRetry(context.Background(), cfg, zap.NewNop(), "op", func() error {
+		calls++
+
+		return validationErr
+	})
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestWithRetry_AbortsOnContextCancellation(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Second, MaxDelay: time.Second, MaxAttempts: 5}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	transientErr := &ProviderError{Action: "create", Err: ErrTransient}
+
+	err := withRetry(ctx, cfg, zap.NewNop(), "op", func() error {
+		return transientErr
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
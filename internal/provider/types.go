@@ -0,0 +1,16 @@
+package provider
+
+// Zone is a DNS zone as exposed by a DNSBackend, kept free of any
+// particular backend's API types so it can be shared across backends.
+type Zone struct {
+	ID   string
+	Name string
+}
+
+// RRSet is a single DNS record set as exposed by a DNSBackend.
+type RRSet struct {
+	ID      string
+	Name    string
+	Type    string
+	Records []string
+}
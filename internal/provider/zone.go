@@ -0,0 +1,31 @@
+package provider
+
+import "strings"
+
+// findBestMatchingZone returns the zone whose name is the longest suffix
+// match of dnsName, mirroring how external-dns picks the most specific zone
+// when more than one could apply, e.g. "example.com" and "sub.example.com"
+// both managed for "foo.sub.example.com".
+func findBestMatchingZone(dnsName string, zones []Zone) (Zone, bool) {
+	candidate := strings.TrimSuffix(dnsName, ".")
+
+	var (
+		best      Zone
+		bestFound bool
+	)
+
+	for _, zone := range zones {
+		name := strings.TrimSuffix(zone.Name, ".")
+
+		if candidate != name && !strings.HasSuffix(candidate, "."+name) {
+			continue
+		}
+
+		if !bestFound || len(name) > len(strings.TrimSuffix(best.Name, ".")) {
+			best = zone
+			bestFound = true
+		}
+	}
+
+	return best, bestFound
+}
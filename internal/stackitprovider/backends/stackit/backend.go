@@ -0,0 +1,175 @@
+// Package stackit adapts the STACKIT DNS swagger client to the generic
+// provider.DNSBackend interface, keeping every swagger-specific request/
+// response shape and error classification out of the shared reconciliation,
+// batching, retry and worker-pool logic in package provider.
+package stackit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-dns-api-client-go"
+
+	"github.com/MichaelEischer/external-dns-stackit-webhook/internal/provider"
+)
+
+// Backend implements provider.DNSBackend against the STACKIT DNS API.
+type Backend struct {
+	apiClient *stackitdnsclient.APIClient
+	projectID string
+}
+
+// NewBackend builds a Backend that manages zones and record sets of
+// projectID through apiClient.
+func NewBackend(apiClient *stackitdnsclient.APIClient, projectID string) *Backend {
+	return &Backend{apiClient: apiClient, projectID: projectID}
+}
+
+// Zones lists every zone of the configured project.
+func (b *Backend) Zones(ctx context.Context) ([]provider.Zone, error) {
+	domainZones, httpResp, err := b.apiClient.ZoneApi.V1ProjectsProjectIdZonesGet(ctx, b.projectID)
+	if err != nil {
+		return nil, newProviderError("list zones", provider.RRSet{}, "", httpResp, err)
+	}
+
+	zones := make([]provider.Zone, 0, len(domainZones.Items))
+	for _, zone := range domainZones.Items {
+		zones = append(zones, provider.Zone{ID: zone.Id, Name: zone.Name})
+	}
+
+	return zones, nil
+}
+
+// ListRRSets lists every record set of the given zone.
+func (b *Backend) ListRRSets(ctx context.Context, zoneID string) ([]provider.RRSet, error) {
+	domainRRSets, httpResp, err := b.apiClient.RecordSetApi.V1ProjectsProjectIdZonesZoneIdRrsetsGet(
+		ctx,
+		b.projectID,
+		zoneID,
+	)
+	if err != nil {
+		return nil, newProviderError("list rrsets", provider.RRSet{}, zoneID, httpResp, err)
+	}
+
+	rrSets := make([]provider.RRSet, 0, len(domainRRSets.RrSets))
+	for _, rrSet := range domainRRSets.RrSets {
+		rrSets = append(rrSets, provider.RRSet{
+			ID:      rrSet.Id,
+			Name:    rrSet.Name,
+			Type:    rrSet.Type_,
+			Records: recordContents(rrSet.Records),
+		})
+	}
+
+	return rrSets, nil
+}
+
+// CreateRRSet creates a new record set in zoneID.
+func (b *Backend) CreateRRSet(ctx context.Context, zoneID string, rrSet provider.RRSet) error {
+	_, httpResp, err := b.apiClient.RecordSetApi.V1ProjectsProjectIdZonesZoneIdRrsetsPost(
+		ctx,
+		toRRSetPost(rrSet),
+		b.projectID,
+		zoneID,
+	)
+
+	return newProviderError(provider.CREATE, rrSet, zoneID, httpResp, err)
+}
+
+// UpdateRRSet overrides the contents of rrSet.ID in zoneID.
+func (b *Backend) UpdateRRSet(ctx context.Context, zoneID string, rrSet provider.RRSet) error {
+	_, httpResp, err := b.apiClient.RecordSetApi.V1ProjectsProjectIdZonesZoneIdRrsetsRrSetIdPatch(
+		ctx,
+		toRRSetPatch(rrSet),
+		b.projectID,
+		zoneID,
+		rrSet.ID,
+	)
+
+	return newProviderError(provider.UPDATE, rrSet, zoneID, httpResp, err)
+}
+
+// DeleteRRSet deletes rrSet.ID in zoneID.
+func (b *Backend) DeleteRRSet(ctx context.Context, zoneID string, rrSet provider.RRSet) error {
+	_, httpResp, err := b.apiClient.RecordSetApi.V1ProjectsProjectIdZonesZoneIdRrsetsRrSetIdDelete(
+		ctx,
+		b.projectID,
+		zoneID,
+		rrSet.ID,
+	)
+
+	return newProviderError(provider.DELETE, rrSet, zoneID, httpResp, err)
+}
+
+// newProviderError wraps a failed API call into a provider.ProviderError,
+// carrying the HTTP status and request ID alongside the operation context,
+// and classifies it so provider.withRetry knows whether it is worth
+// retrying: 429/5xx and errors without an HTTP response (e.g. a dropped
+// connection) are, 4xx validation errors are not.
+func newProviderError(action string, rrSet provider.RRSet, zoneID string, httpResp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	perr := &provider.ProviderError{
+		Action:     action,
+		DNSName:    rrSet.Name,
+		RecordType: rrSet.Type,
+		ZoneID:     zoneID,
+		RRSetID:    rrSet.ID,
+	}
+
+	if httpResp != nil {
+		perr.HTTPStatus = httpResp.StatusCode
+		perr.APIRequestID = httpResp.Header.Get("X-Request-Id")
+	}
+
+	switch {
+	case httpResp == nil:
+		perr.Err = fmt.Errorf("%w: %v", provider.ErrTransient, err)
+	case httpResp.StatusCode == http.StatusTooManyRequests:
+		perr.Err = fmt.Errorf("%w: %v", provider.ErrRateLimited, err)
+	case httpResp.StatusCode >= http.StatusInternalServerError:
+		perr.Err = fmt.Errorf("%w: %v", provider.ErrTransient, err)
+	case httpResp.StatusCode >= http.StatusBadRequest:
+		perr.Err = fmt.Errorf("%w: %v", provider.ErrValidation, err)
+	default:
+		perr.Err = err
+	}
+
+	return perr
+}
+
+func recordContents(records []stackitdnsclient.RrSetRecord) []string {
+	contents := make([]string, 0, len(records))
+	for _, record := range records {
+		contents = append(contents, record.Content)
+	}
+
+	return contents
+}
+
+func toRRSetPost(rrSet provider.RRSet) stackitdnsclient.RrSetPost {
+	return stackitdnsclient.RrSetPost{
+		Name:    rrSet.Name,
+		Type_:   rrSet.Type,
+		Records: toRecordPosts(rrSet.Records),
+	}
+}
+
+func toRRSetPatch(rrSet provider.RRSet) stackitdnsclient.RrSetPatch {
+	return stackitdnsclient.RrSetPatch{
+		Name:    rrSet.Name,
+		Records: toRecordPosts(rrSet.Records),
+	}
+}
+
+func toRecordPosts(values []string) []stackitdnsclient.RrSetRecordPost {
+	records := make([]stackitdnsclient.RrSetRecordPost, 0, len(values))
+	for _, value := range values {
+		records = append(records, stackitdnsclient.RrSetRecordPost{Content: value})
+	}
+
+	return records
+}
@@ -0,0 +1,31 @@
+// Package stackitprovider wires the generic provider.Provider to the
+// STACKIT DNS backend, giving the webhook server a single call that builds
+// the external-dns-facing provider. This replaces the old StackitDNSProvider
+// as the construction entry point.
+package stackitprovider
+
+import (
+	stackitdnsclient "github.com/stackitcloud/stackit-dns-api-client-go"
+	"go.uber.org/zap"
+
+	"github.com/MichaelEischer/external-dns-stackit-webhook/internal/provider"
+	"github.com/MichaelEischer/external-dns-stackit-webhook/internal/stackitprovider/backends/stackit"
+)
+
+// New builds the provider.Provider that backs this webhook's endpoints,
+// managing projectID's zones and record sets through apiClient via the
+// STACKIT backend.
+func New(
+	apiClient *stackitdnsclient.APIClient,
+	projectID string,
+	logger *zap.Logger,
+	dryRun bool,
+	workers int,
+	retryConfig provider.RetryConfig,
+	maxChangesPerBatch int,
+	reconcileConfig provider.ReconcileConfig,
+) *provider.Provider {
+	backend := stackit.NewBackend(apiClient, projectID)
+
+	return provider.NewProvider(backend, logger, dryRun, workers, retryConfig, maxChangesPerBatch, reconcileConfig)
+}